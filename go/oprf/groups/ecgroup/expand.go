@@ -0,0 +1,134 @@
+package ecgroup
+
+import (
+	"hash"
+	"math/big"
+
+	gg "github.com/alxdavids/oprf-poc/go/oprf/groups"
+	"golang.org/x/crypto/sha3"
+)
+
+// expand selects which expand_message variant a h2cParams uses to produce
+// the uniform bytes consumed by hash_to_field, see
+// https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-07#section-5.3.
+const (
+	expandXMD = iota
+	expandXOF
+)
+
+// oversizeDSTPrefix is prepended to an over-long DST before it is hashed
+// down, per the draft's longer-DST fallback.
+var oversizeDSTPrefix = []byte("H2C-OVERSIZE-DST-")
+
+// dstPrime appends the length-prefix octet to dst, or, if dst itself is
+// longer than 255 bytes, replaces it with hashDST(oversizeDSTPrefix || dst)
+// before doing so.
+func dstPrime(dst []byte, hashDST func([]byte) []byte) ([]byte, error) {
+	if len(dst) > 255 {
+		dst = hashDST(append(append([]byte{}, oversizeDSTPrefix...), dst...))
+	}
+	lenTag, err := i2osp(big.NewInt(int64(len(dst))), 1)
+	if err != nil {
+		return nil, gg.ErrInternalInstantiation
+	}
+	return append(append([]byte{}, dst...), lenTag...), nil
+}
+
+// expandMessageXMD implements expand_message_xmd from
+// draft-irtf-cfrg-hash-to-curve-07, section 5.3.1, using a Merkle-Damgard
+// hash function such as SHA-256/SHA-512.
+func expandMessageXMD(newHash func() hash.Hash, msg, dst []byte, lenInBytes int) ([]byte, error) {
+	h := newHash()
+	bInBytes := h.Size()
+	sInBytes := h.BlockSize()
+	ell := (lenInBytes + bInBytes - 1) / bInBytes
+	if ell > 255 || lenInBytes > 65535 {
+		return nil, gg.ErrInternalInstantiation
+	}
+	dstP, err := dstPrime(dst, func(b []byte) []byte {
+		h := newHash()
+		h.Reset()
+		h.Write(b)
+		return h.Sum(nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	lIBStr, err := i2osp(big.NewInt(int64(lenInBytes)), 2)
+	if err != nil {
+		return nil, gg.ErrInternalInstantiation
+	}
+	zPad := make([]byte, sInBytes)
+	zero1, err := i2osp(zero, 1)
+	if err != nil {
+		return nil, gg.ErrInternalInstantiation
+	}
+
+	h.Reset()
+	h.Write(zPad)
+	h.Write(msg)
+	h.Write(lIBStr)
+	h.Write(zero1)
+	h.Write(dstP)
+	b0 := h.Sum(nil)
+
+	one1, err := i2osp(one, 1)
+	if err != nil {
+		return nil, gg.ErrInternalInstantiation
+	}
+	h.Reset()
+	h.Write(b0)
+	h.Write(one1)
+	h.Write(dstP)
+	bPrev := h.Sum(nil)
+
+	uniformBytes := append([]byte{}, bPrev...)
+	for i := 2; i <= ell; i++ {
+		xored := make([]byte, bInBytes)
+		for j := range xored {
+			xored[j] = b0[j] ^ bPrev[j]
+		}
+		iStr, err := i2osp(big.NewInt(int64(i)), 1)
+		if err != nil {
+			return nil, gg.ErrInternalInstantiation
+		}
+		h.Reset()
+		h.Write(xored)
+		h.Write(iStr)
+		h.Write(dstP)
+		bPrev = h.Sum(nil)
+		uniformBytes = append(uniformBytes, bPrev...)
+	}
+	return uniformBytes[:lenInBytes], nil
+}
+
+// expandMessageXOF implements expand_message_xof from
+// draft-irtf-cfrg-hash-to-curve-07, section 5.3.2, using an extendable
+// output function such as SHAKE128/SHAKE256.
+func expandMessageXOF(newXOF func() sha3.ShakeHash, msg, dst []byte, lenInBytes int) ([]byte, error) {
+	if lenInBytes > 65535 || len(dst) > 255 {
+		return nil, gg.ErrInternalInstantiation
+	}
+	dstP, err := dstPrime(dst, func(b []byte) []byte {
+		out := make([]byte, len(b))
+		x := newXOF()
+		x.Write(b)
+		x.Read(out)
+		return out
+	})
+	if err != nil {
+		return nil, err
+	}
+	lIBStr, err := i2osp(big.NewInt(int64(lenInBytes)), 2)
+	if err != nil {
+		return nil, gg.ErrInternalInstantiation
+	}
+
+	x := newXOF()
+	x.Write(msg)
+	x.Write(lIBStr)
+	x.Write(dstP)
+	uniformBytes := make([]byte, lenInBytes)
+	x.Read(uniformBytes)
+	return uniformBytes, nil
+}
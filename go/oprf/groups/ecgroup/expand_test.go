@@ -0,0 +1,228 @@
+package ecgroup
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"math/rand"
+	"os"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+type expandVector struct {
+	Name         string `json:"name"`
+	Hash         string `json:"hash"`
+	DST          string `json:"dst"`
+	Msg          string `json:"msg"`
+	LenInBytes   int    `json:"lenInBytes"`
+	UniformBytes string `json:"uniformBytes"`
+}
+
+// TestExpandMessageXMD checks expandMessageXMD against the P-384/P-521
+// expand_message_xmd(SHA-512) vectors in testdata/expand_message_xmd.json.
+// Those vectors (like the expand_message_xof ones below) are this project's
+// own self-generated fixtures, cross-checked against an independent Python
+// re-implementation rather than taken from the hash-to-curve draft appendix
+// — see each fixture's "source" field, and
+// TestExpandMessageXMDAgainstReference below for draft-conformance coverage
+// that doesn't depend on a static fixture at all.
+func TestExpandMessageXMD(t *testing.T) {
+	raw, err := os.ReadFile("testdata/expand_message_xmd.json")
+	if err != nil {
+		t.Fatalf("failed to read test vectors: %v", err)
+	}
+	var doc struct {
+		Vectors []expandVector `json:"vectors"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("failed to parse test vectors: %v", err)
+	}
+
+	for _, v := range doc.Vectors {
+		v := v
+		t.Run(v.Name, func(t *testing.T) {
+			want, err := hex.DecodeString(v.UniformBytes)
+			if err != nil {
+				t.Fatalf("bad uniformBytes fixture: %v", err)
+			}
+			got, err := expandMessageXMD(func() hash.Hash { return sha512.New() }, []byte(v.Msg), []byte(v.DST), v.LenInBytes)
+			if err != nil {
+				t.Fatalf("expandMessageXMD failed: %v", err)
+			}
+			if hex.EncodeToString(got) != hex.EncodeToString(want) {
+				t.Fatalf("expandMessageXMD(%s) = %x, want %x", v.Name, got, want)
+			}
+		})
+	}
+}
+
+type xofVector struct {
+	Name         string `json:"name"`
+	XOF          string `json:"xof"`
+	DST          string `json:"dst"`
+	Msg          string `json:"msg"`
+	LenInBytes   int    `json:"lenInBytes"`
+	UniformBytes string `json:"uniformBytes"`
+}
+
+// TestExpandMessageXOF checks expandMessageXOF against the Curve25519/
+// Ristretto255 expand_message_xof(SHAKE-256) vectors in
+// testdata/expand_message_xof.json. Those two suites are the only callers of
+// expandMessageXOF (see h2cParams.expand in getH2CParams), but it previously
+// had no test coverage of its own at all.
+func TestExpandMessageXOF(t *testing.T) {
+	raw, err := os.ReadFile("testdata/expand_message_xof.json")
+	if err != nil {
+		t.Fatalf("failed to read test vectors: %v", err)
+	}
+	var doc struct {
+		Vectors []xofVector `json:"vectors"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("failed to parse test vectors: %v", err)
+	}
+
+	for _, v := range doc.Vectors {
+		v := v
+		t.Run(v.Name, func(t *testing.T) {
+			want, err := hex.DecodeString(v.UniformBytes)
+			if err != nil {
+				t.Fatalf("bad uniformBytes fixture: %v", err)
+			}
+			got, err := expandMessageXOF(sha3.NewShake256, []byte(v.Msg), []byte(v.DST), v.LenInBytes)
+			if err != nil {
+				t.Fatalf("expandMessageXOF failed: %v", err)
+			}
+			if hex.EncodeToString(got) != hex.EncodeToString(want) {
+				t.Fatalf("expandMessageXOF(%s) = %x, want %x", v.Name, got, want)
+			}
+		})
+	}
+}
+
+// refI2OSP is a from-scratch (not shared with expand.go's i2osp) big-endian
+// encoding of a small non-negative int into xLen bytes, used only to build
+// the reference implementations below.
+func refI2OSP(x, xLen int) []byte {
+	buf := make([]byte, xLen)
+	for i := xLen - 1; i >= 0 && x > 0; i-- {
+		buf[i] = byte(x & 0xff)
+		x >>= 8
+	}
+	return buf
+}
+
+// refExpandMessageXMD is an independent re-implementation of
+// expand_message_xmd (draft-irtf-cfrg-hash-to-curve-07, section 5.3.1),
+// written directly from the spec's pseudocode rather than sharing any code
+// with expand.go's expandMessageXMD. It intentionally skips the long-DST
+// fallback (oversized-DST hashing), since none of the fuzzed inputs below
+// exceed 255 bytes.
+func refExpandMessageXMD(newHash func() hash.Hash, msg, dst []byte, lenInBytes int) []byte {
+	h := newHash()
+	bInBytes := h.Size()
+	sInBytes := h.BlockSize()
+	ell := (lenInBytes + bInBytes - 1) / bInBytes
+	dstPrime := append(append([]byte{}, dst...), refI2OSP(len(dst), 1)...)
+	msgPrime := append([]byte{}, make([]byte, sInBytes)...)
+	msgPrime = append(msgPrime, msg...)
+	msgPrime = append(msgPrime, refI2OSP(lenInBytes, 2)...)
+	msgPrime = append(msgPrime, refI2OSP(0, 1)...)
+	msgPrime = append(msgPrime, dstPrime...)
+
+	h.Reset()
+	h.Write(msgPrime)
+	b0 := h.Sum(nil)
+
+	h.Reset()
+	h.Write(b0)
+	h.Write(refI2OSP(1, 1))
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+
+	uniformBytes := append([]byte{}, bi...)
+	for i := 2; i <= ell; i++ {
+		xored := make([]byte, bInBytes)
+		for j := range xored {
+			xored[j] = b0[j] ^ bi[j]
+		}
+		h.Reset()
+		h.Write(xored)
+		h.Write(refI2OSP(i, 1))
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		uniformBytes = append(uniformBytes, bi...)
+	}
+	return uniformBytes[:lenInBytes]
+}
+
+// refExpandMessageXOF is the expand_message_xof (section 5.3.2) counterpart
+// of refExpandMessageXMD, again written independently of expand.go.
+func refExpandMessageXOF(newXOF func() sha3.ShakeHash, msg, dst []byte, lenInBytes int) []byte {
+	dstPrime := append(append([]byte{}, dst...), refI2OSP(len(dst), 1)...)
+	x := newXOF()
+	x.Write(msg)
+	x.Write(refI2OSP(lenInBytes, 2))
+	x.Write(dstPrime)
+	out := make([]byte, lenInBytes)
+	x.Read(out)
+	return out
+}
+
+// TestExpandMessageXMDAgainstReference fuzzes expandMessageXMD against
+// refExpandMessageXMD, an independent implementation written directly from
+// the draft's pseudocode. The hash-to-curve draft appendix vectors can't be
+// reproduced safely here (this is an offline sandbox with no way to verify a
+// transcribed 90-plus-byte hex constant against the actual spec text), so
+// rather than risk shipping fabricated "official" vectors, this checks
+// expandMessageXMD against a second implementation across many random
+// message/DST/length combinations, which catches the same class of bug
+// (wrong length encoding, wrong block XOR, off-by-one in ell) that a couple
+// of static vectors would.
+func TestExpandMessageXMDAgainstReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for _, newHash := range []func() hash.Hash{sha256.New, sha512.New} {
+		for trial := 0; trial < 200; trial++ {
+			msg := make([]byte, rng.Intn(128))
+			rng.Read(msg)
+			dst := make([]byte, 16+rng.Intn(64))
+			rng.Read(dst)
+			lenInBytes := 1 + rng.Intn(256)
+
+			got, err := expandMessageXMD(newHash, msg, dst, lenInBytes)
+			if err != nil {
+				t.Fatalf("expandMessageXMD failed: %v", err)
+			}
+			want := refExpandMessageXMD(newHash, msg, dst, lenInBytes)
+			if hex.EncodeToString(got) != hex.EncodeToString(want) {
+				t.Fatalf("expandMessageXMD(msg=%x, dst=%x, len=%d) = %x, want %x", msg, dst, lenInBytes, got, want)
+			}
+		}
+	}
+}
+
+// TestExpandMessageXOFAgainstReference is the expandMessageXOF counterpart
+// of TestExpandMessageXMDAgainstReference, fuzzed against refExpandMessageXOF.
+func TestExpandMessageXOFAgainstReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	for trial := 0; trial < 200; trial++ {
+		msg := make([]byte, rng.Intn(128))
+		rng.Read(msg)
+		dst := make([]byte, 16+rng.Intn(64))
+		rng.Read(dst)
+		lenInBytes := 1 + rng.Intn(256)
+
+		got, err := expandMessageXOF(sha3.NewShake256, msg, dst, lenInBytes)
+		if err != nil {
+			t.Fatalf("expandMessageXOF failed: %v", err)
+		}
+		want := refExpandMessageXOF(sha3.NewShake256, msg, dst, lenInBytes)
+		if hex.EncodeToString(got) != hex.EncodeToString(want) {
+			t.Fatalf("expandMessageXOF(msg=%x, dst=%x, len=%d) = %x, want %x", msg, dst, lenInBytes, got, want)
+		}
+	}
+}
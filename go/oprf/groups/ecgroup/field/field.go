@@ -0,0 +1,301 @@
+// Package field implements fixed-size, constant-time prime-field arithmetic
+// for the moduli used by the ecgroup hash-to-curve implementations (P-384
+// and P-521). Elements are stored in Montgomery form over 64-bit limbs, in
+// the style of nistec/fiat-crypto generated field code, so that none of the
+// operations branch or allocate based on the magnitude of an operand the way
+// math/big's arbitrary-precision routines do.
+package field
+
+import "math/big"
+
+// maxLimbs is sized to hold the P-521 modulus (521 bits -> 9 64-bit limbs).
+const maxLimbs = 9
+
+// Params describes a prime modulus in the fixed-limb Montgomery
+// representation used by Element. A Params value is immutable once built by
+// NewParams and is shared by every Element reduced modulo it.
+type Params struct {
+	limbs    int               // number of 64-bit limbs needed for this modulus
+	modulus  [maxLimbs]uint64  // p, little-endian limbs
+	rSquared [maxLimbs]uint64  // R^2 mod p, used to enter Montgomery form
+	nPrime   uint64            // -p^-1 mod 2^64, the Montgomery reduction constant
+	bitLen   int               // bit length of p, used to size exponentiation loops
+}
+
+// NewParams builds the fixed-limb Montgomery parameters for the prime p. It
+// is only ever called with the hard-coded curve moduli at package init time,
+// so using math/big here does not reintroduce a variable-time code path into
+// any operation performed on secret data.
+func NewParams(p *big.Int) *Params {
+	bitLen := p.BitLen()
+	limbs := (bitLen + 63) / 64
+	params := &Params{limbs: limbs, bitLen: bitLen}
+	copy(params.modulus[:], toLimbs(p, limbs))
+
+	r := new(big.Int).Lsh(big.NewInt(1), uint(64*limbs))
+	rSquared := new(big.Int).Mod(new(big.Int).Mul(r, r), p)
+	copy(params.rSquared[:], toLimbs(rSquared, limbs))
+
+	// nPrime = -p^-1 mod 2^64, computed via the standard Newton iteration
+	// on the bottom limb so that MontMul's reduction stays limb-local.
+	pInv := params.modulus[0]
+	for i := 0; i < 5; i++ {
+		pInv = pInv * (2 - params.modulus[0]*pInv)
+	}
+	params.nPrime = -pInv
+
+	return params
+}
+
+// toLimbs splits x into n little-endian 64-bit limbs.
+func toLimbs(x *big.Int, n int) []uint64 {
+	limbs := make([]uint64, n)
+	mask := new(big.Int).SetUint64(^uint64(0))
+	t := new(big.Int).Set(x)
+	for i := 0; i < n; i++ {
+		limbs[i] = new(big.Int).And(t, mask).Uint64()
+		t.Rsh(t, 64)
+	}
+	return limbs
+}
+
+// Element is a field element reduced modulo some Params, held in Montgomery
+// form. The zero value is not meaningful on its own; use Params.Zero or
+// Params.FromBig to construct one.
+type Element struct {
+	params *Params
+	limbs  [maxLimbs]uint64
+}
+
+// FromBig reduces x modulo p and returns the corresponding Element in
+// Montgomery form. This is the boundary between the math/big world used by
+// callers and the fixed-limb arithmetic used internally.
+func (p *Params) FromBig(x *big.Int) Element {
+	reduced := new(big.Int).Mod(x, p.bigModulus())
+	var e Element
+	e.params = p
+	var tmp [maxLimbs]uint64
+	copy(tmp[:], toLimbs(reduced, p.limbs))
+	e = p.montMul(Element{params: p, limbs: tmp}, Element{params: p, limbs: p.rSquared})
+	return e
+}
+
+// ToBig converts e out of Montgomery form and returns it as a *big.Int. This
+// is the only place Element values re-enter math/big, and it is used at API
+// boundaries (e.g. constructing curve points), never on a hot secret path.
+func (e Element) ToBig() *big.Int {
+	one := Element{params: e.params, limbs: [maxLimbs]uint64{1}}
+	plain := e.params.montMul(e, one)
+	result := new(big.Int)
+	for i := e.params.limbs - 1; i >= 0; i-- {
+		result.Lsh(result, 64)
+		result.Or(result, new(big.Int).SetUint64(plain.limbs[i]))
+	}
+	return result
+}
+
+// Sgn0 returns the least-significant bit of e's canonical (out-of-Montgomery,
+// reduced mod p) representative, i.e. the spec's sgn0_m_eq_1(e). Unlike
+// ToBig, this stays on the fixed-limb path end to end, so callers that only
+// need the sign bit (e.g. the hash-to-curve maps' final sign fix-up) never
+// have to round-trip a secret-derived element through math/big to get it.
+func (e Element) Sgn0() uint64 {
+	one := Element{params: e.params, limbs: [maxLimbs]uint64{1}}
+	plain := e.params.montMul(e, one)
+	return plain.limbs[0] & 1
+}
+
+// Modulus returns the prime p underlying these Params, as a *big.Int. This
+// is a boundary accessor for callers that need to derive public exponents
+// (e.g. p-2 for inversion, (p-1)/2 for the Legendre exponent) to hand to Pow.
+func (p *Params) Modulus() *big.Int {
+	return p.bigModulus()
+}
+
+func (p *Params) bigModulus() *big.Int {
+	m := new(big.Int)
+	for i := p.limbs - 1; i >= 0; i-- {
+		m.Lsh(m, 64)
+		m.Or(m, new(big.Int).SetUint64(p.modulus[i]))
+	}
+	return m
+}
+
+// Zero returns the additive identity for p.
+func (p *Params) Zero() Element {
+	return Element{params: p}
+}
+
+// montMul performs constant-time Montgomery multiplication (CIOS) of a and
+// b, both already reduced modulo params.modulus.
+func (p *Params) montMul(a, b Element) Element {
+	var t [maxLimbs + 1]uint64
+	n := p.limbs
+	for i := 0; i < n; i++ {
+		// t += a * b[i]
+		var carry uint64
+		for j := 0; j < n; j++ {
+			hi, lo := bits64Mul(a.limbs[j], b.limbs[i])
+			lo, c := bits64Add(lo, t[j], 0)
+			hi, _ = bits64Add(hi, 0, c)
+			lo, c = bits64Add(lo, carry, 0)
+			hi, _ = bits64Add(hi, 0, c)
+			t[j] = lo
+			carry = hi
+		}
+		t[n], _ = bits64Add(t[n], carry, 0)
+
+		// m = t[0] * nPrime mod 2^64; t += m * modulus, then shift right one limb
+		m := t[0] * p.nPrime
+		carry = 0
+		for j := 0; j < n; j++ {
+			hi, lo := bits64Mul(m, p.modulus[j])
+			lo, c := bits64Add(lo, t[j], 0)
+			hi, _ = bits64Add(hi, 0, c)
+			lo, c = bits64Add(lo, carry, 0)
+			hi, _ = bits64Add(hi, 0, c)
+			t[j] = lo
+			carry = hi
+		}
+		t[n], carry = bits64Add(t[n], carry, 0)
+		for j := 0; j < n; j++ {
+			t[j] = t[j+1]
+		}
+		t[n] = carry
+	}
+
+	return Element{params: p, limbs: p.reduceWide(t)}
+}
+
+// reduceWide conditionally subtracts the modulus from the (n+1)-limb value
+// t, in constant time, and returns the result as n limbs. montMul/Add both
+// produce a result that is strictly less than 2p but does not necessarily
+// fit in n limbs on its own (p can use the full n limbs, e.g. P-384's
+// modulus is exactly 6x64 bits), so the top limb t[n] (always 0 or 1) has to
+// participate in the comparison/subtraction — dropping it and reducing only
+// t[:n] silently produces a wrong result whenever t[n] == 1.
+func (p *Params) reduceWide(t [maxLimbs + 1]uint64) [maxLimbs]uint64 {
+	n := p.limbs
+	var diff [maxLimbs]uint64
+	var borrow uint64
+	for i := 0; i < n; i++ {
+		diff[i], borrow = bits64Sub(t[i], p.modulus[i], borrow)
+	}
+	// p's implicit top limb is 0, so this subtracts the remaining borrow
+	// from t[n] (itself 0 or 1); a borrow here means t < p.
+	_, topBorrow := bits64Sub(t[n], 0, borrow)
+
+	var out [maxLimbs]uint64
+	copy(out[:n], t[:n])
+	cmovLimbs(&out, diff, n, 1-topBorrow)
+	return out
+}
+
+func cmovLimbs(dst *[maxLimbs]uint64, src [maxLimbs]uint64, n int, move uint64) {
+	mask := -move // all-ones if move == 1, all-zero otherwise
+	for i := 0; i < n; i++ {
+		dst[i] = dst[i]&^mask | src[i]&mask
+	}
+}
+
+func bits64Add(x, y, carryIn uint64) (sum, carryOut uint64) {
+	sum = x + y + carryIn
+	carryOut = ((x & y) | ((x | y) &^ sum)) >> 63
+	return
+}
+
+func bits64Sub(x, y, borrowIn uint64) (diff, borrowOut uint64) {
+	diff = x - y - borrowIn
+	borrowOut = ((^x & y) | (^(x ^ y) & diff)) >> 63
+	return
+}
+
+func bits64Mul(x, y uint64) (hi, lo uint64) {
+	const mask32 = 1<<32 - 1
+	x0, x1 := x&mask32, x>>32
+	y0, y1 := y&mask32, y>>32
+	w0 := x0 * y0
+	t := x1*y0 + w0>>32
+	w1 := t & mask32
+	w2 := t >> 32
+	w1 += x0 * y1
+	hi = x1*y1 + w2 + w1>>32
+	lo = x * y
+	return
+}
+
+// Add returns a+b mod p in constant time.
+func (p *Params) Add(a, b Element) Element {
+	var t [maxLimbs + 1]uint64
+	var carry uint64
+	for i := 0; i < p.limbs; i++ {
+		t[i], carry = bits64Add(a.limbs[i], b.limbs[i], carry)
+	}
+	t[p.limbs] = carry
+	return Element{params: p, limbs: p.reduceWide(t)}
+}
+
+// Sub returns a-b mod p in constant time.
+func (p *Params) Sub(a, b Element) Element {
+	var diff [maxLimbs]uint64
+	var borrow uint64
+	for i := 0; i < p.limbs; i++ {
+		diff[i], borrow = bits64Sub(a.limbs[i], b.limbs[i], borrow)
+	}
+	out := Element{params: p, limbs: diff}
+	// borrow == 1 means the subtraction wrapped, so add p back once
+	var wrapped [maxLimbs]uint64
+	var carry uint64
+	for i := 0; i < p.limbs; i++ {
+		wrapped[i], carry = bits64Add(out.limbs[i], p.modulus[i], carry)
+	}
+	cmovLimbs(&out.limbs, wrapped, p.limbs, borrow)
+	return out
+}
+
+// Mul returns a*b mod p in constant time.
+func (p *Params) Mul(a, b Element) Element {
+	return p.montMul(a, b)
+}
+
+// Square returns a*a mod p in constant time.
+func (p *Params) Square(a Element) Element {
+	return p.montMul(a, a)
+}
+
+// CMov sets a to b if move == 1, leaving it unchanged if move == 0, without
+// branching on move.
+func (p *Params) CMov(a, b Element, move uint64) Element {
+	var out [maxLimbs]uint64
+	out = a.limbs
+	cmovLimbs(&out, b.limbs, p.limbs, move)
+	return Element{params: p, limbs: out}
+}
+
+// IsZero reports, in constant time, whether e is the additive identity.
+// It returns 1 if e == 0 and 0 otherwise.
+func (e Element) IsZero() uint64 {
+	var acc uint64
+	for i := 0; i < e.params.limbs; i++ {
+		acc |= e.limbs[i]
+	}
+	return 1 ^ ((acc | -acc) >> 63)
+}
+
+// Pow returns a^exp mod p via a fixed-iteration-count square-and-multiply
+// ladder. exp is always a public value derived from the modulus (p-2 for
+// inversion, (p-1)/2 for the Legendre symbol and (p+1)/4 for the square root
+// used by sswu), so branching
+// on its bits does not leak anything about the secret base a; every
+// iteration still performs exactly one square and one constant-time cmov'd
+// multiply, so the cost is independent of a.
+func (p *Params) Pow(a Element, exp *big.Int) Element {
+	result := p.FromBig(big.NewInt(1))
+	base := a
+	for i := 0; i < exp.BitLen(); i++ {
+		multiplied := p.Mul(result, base)
+		result = p.CMov(result, multiplied, uint64(exp.Bit(i)))
+		base = p.Square(base)
+	}
+	return result
+}
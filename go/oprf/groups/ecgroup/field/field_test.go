@@ -0,0 +1,94 @@
+package field
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// p384 is the NIST P-384 field modulus: 6 64-bit limbs with no headroom,
+// which is exactly the tight fit that exposed the dropped-carry bug in
+// montMul/Add (P-521's 9-limb/576-bit buffer has slack and masked it).
+var p384, _ = new(big.Int).SetString("39402006196394479212279040100143613805079739270465446667948293404245721771496870329047266088258938001861606973112319", 10)
+
+// p521 is the NIST P-521 field modulus, included for contrast since it has
+// an extra limb of slack relative to its bit length.
+var p521, _ = new(big.Int).SetString("6864797660130609714981900799081393217269435300143305409394463459185543183397656052122559640661454554977296311391480858037121987999716643812574028291115057151", 10)
+
+func randBigInt(rng *rand.Rand, max *big.Int) *big.Int {
+	return new(big.Int).Rand(rng, max)
+}
+
+func testAddAgainstBig(t *testing.T, p *big.Int, trials int) {
+	params := NewParams(p)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < trials; i++ {
+		a := randBigInt(rng, p)
+		b := randBigInt(rng, p)
+		got := params.Add(params.FromBig(a), params.FromBig(b)).ToBig()
+		want := new(big.Int).Mod(new(big.Int).Add(a, b), p)
+		if got.Cmp(want) != 0 {
+			t.Fatalf("Add(%s, %s) = %s, want %s", a, b, got, want)
+		}
+	}
+	// the case explicitly called out in review: (p-1) + (p-1) = p-2
+	pMinusOne := new(big.Int).Sub(p, big.NewInt(1))
+	got := params.Add(params.FromBig(pMinusOne), params.FromBig(pMinusOne)).ToBig()
+	want := new(big.Int).Sub(p, big.NewInt(2))
+	if got.Cmp(want) != 0 {
+		t.Fatalf("Add(p-1, p-1) = %s, want %s", got, want)
+	}
+}
+
+func testMulAgainstBig(t *testing.T, p *big.Int, trials int) {
+	params := NewParams(p)
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < trials; i++ {
+		a := randBigInt(rng, p)
+		b := randBigInt(rng, p)
+		got := params.Mul(params.FromBig(a), params.FromBig(b)).ToBig()
+		want := new(big.Int).Mod(new(big.Int).Mul(a, b), p)
+		if got.Cmp(want) != 0 {
+			t.Fatalf("Mul(%s, %s) = %s, want %s", a, b, got, want)
+		}
+	}
+}
+
+func testSquareAgainstBig(t *testing.T, p *big.Int, trials int) {
+	params := NewParams(p)
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < trials; i++ {
+		a := randBigInt(rng, p)
+		got := params.Square(params.FromBig(a)).ToBig()
+		want := new(big.Int).Mod(new(big.Int).Mul(a, a), p)
+		if got.Cmp(want) != 0 {
+			t.Fatalf("Square(%s) = %s, want %s", a, got, want)
+		}
+	}
+}
+
+func TestAddAgainstBigP384(t *testing.T) { testAddAgainstBig(t, p384, 2000) }
+func TestMulAgainstBigP384(t *testing.T) { testMulAgainstBig(t, p384, 2000) }
+func TestSquareAgainstBigP384(t *testing.T) { testSquareAgainstBig(t, p384, 2000) }
+
+func TestAddAgainstBigP521(t *testing.T) { testAddAgainstBig(t, p521, 2000) }
+func TestMulAgainstBigP521(t *testing.T) { testMulAgainstBig(t, p521, 2000) }
+func TestSquareAgainstBigP521(t *testing.T) { testSquareAgainstBig(t, p521, 2000) }
+
+// TestPowAgainstBig checks Pow (used for inversion and the Legendre/sqrt
+// exponent) against math/big.Int.Exp for both moduli.
+func TestPowAgainstBig(t *testing.T) {
+	for _, p := range []*big.Int{p384, p521} {
+		params := NewParams(p)
+		rng := rand.New(rand.NewSource(4))
+		modMinusTwo := new(big.Int).Sub(p, big.NewInt(2))
+		for i := 0; i < 200; i++ {
+			a := randBigInt(rng, p)
+			got := params.Pow(params.FromBig(a), modMinusTwo).ToBig()
+			want := new(big.Int).Exp(a, modMinusTwo, p)
+			if got.Cmp(want) != 0 {
+				t.Fatalf("Pow(%s, p-2) = %s, want %s", a, got, want)
+			}
+		}
+	}
+}
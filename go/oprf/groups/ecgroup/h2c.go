@@ -5,7 +5,8 @@ import (
 	"math/big"
 
 	gg "github.com/alxdavids/oprf-poc/go/oprf/groups"
-	"golang.org/x/crypto/hkdf"
+	"github.com/alxdavids/oprf-poc/go/oprf/groups/ecgroup/field"
+	"golang.org/x/crypto/sha3"
 )
 
 // big.Int constants
@@ -13,6 +14,13 @@ var (
 	zero, one, minusOne, two *big.Int = big.NewInt(0), big.NewInt(1), big.NewInt(-1), big.NewInt(2)
 )
 
+// mapping selects which curve-mapping algorithm a h2cParams should use to
+// encode field elements as curve points.
+const (
+	sswuMapping = iota
+	elligator2Mapping
+)
+
 // h2cParams contains all of the parameters required for computing the
 // hash_to_curve mapping algorithm, see
 // https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-05 for more
@@ -29,71 +37,130 @@ type h2cParams struct {
 	hash    hash.Hash
 	l       int
 	hEff    *big.Int
+	field   *field.Params
+	expand  int
+	xof     func() sha3.ShakeHash
 }
 
-// getH2CParams returns the h2cParams object for the specified curve
+// getH2CParams returns the h2cParams object for the specified curve. Note
+// that the Curve25519/Ristretto255 cases below (and the matching dispatch
+// in hashToCurve) are only reachable once this package registers a
+// GroupCurve for one of those names; no such GroupCurve exists here today,
+// so those cases are presently dead code, not working suites. There is no
+// BLS12-381-G2 case: that name gets ErrUnsupportedGroup.
 func getH2CParams(gc GroupCurve) (h2cParams, error) {
 	switch gc.Name() {
 	case "P-384":
 		return h2cParams{
 			gc:      gc,
 			dst:     []byte("VOPRF-P384-SHA512-SSWU-RO-"),
-			mapping: 0,
+			mapping: sswuMapping,
 			z:       -12,
 			a:       big.NewInt(-3),
 			b:       gc.ops.Params().B,
 			p:       gc.Order(),
+			field:   field.NewParams(gc.Order()),
 			m:       1,
 			hash:    gc.Hash(),
 			l:       72,
 			hEff:    one,
+			expand:  expandXMD,
 		}, nil
 	case "P-521":
 		return h2cParams{
 			gc:      gc,
 			dst:     []byte("VOPRF-P521-SHA512-SSWU-RO-"),
-			mapping: 0,
+			mapping: sswuMapping,
 			z:       -4,
 			a:       big.NewInt(-3),
 			b:       gc.ops.Params().B,
 			p:       gc.Order(),
+			field:   field.NewParams(gc.Order()),
 			m:       1,
 			hash:    gc.Hash(),
 			l:       96,
 			hEff:    one,
+			expand:  expandXMD,
+		}, nil
+	case "Curve25519":
+		return h2cParams{
+			gc:      gc,
+			dst:     []byte("VOPRF-curve25519-SHA512-ELL2-RO-"),
+			mapping: elligator2Mapping,
+			z:       2,
+			a:       gc.ops.Params().B, // re-used to carry the Montgomery A coefficient (486662)
+			b:       one,
+			p:       gc.Order(),
+			field:   field.NewParams(gc.Order()),
+			m:       1,
+			hash:    gc.Hash(),
+			l:       48,
+			hEff:    big.NewInt(8),
+			expand:  expandXOF,
+			xof:     sha3.NewShake256,
+		}, nil
+	case "Ristretto255":
+		return h2cParams{
+			gc:      gc,
+			dst:     []byte("VOPRF-ristretto255-SHA512-ELL2-RO-"),
+			mapping: elligator2Mapping,
+			z:       2,
+			a:       gc.ops.Params().B,
+			b:       one,
+			p:       gc.Order(),
+			field:   field.NewParams(gc.Order()),
+			m:       1,
+			hash:    gc.Hash(),
+			l:       48,
+			hEff:    big.NewInt(8),
+			expand:  expandXOF,
+			xof:     sha3.NewShake256,
 		}, nil
+	case "secp256k1":
+		// secp256k1 isn't SSWU-compatible directly (its a coefficient is 0),
+		// so the suite maps onto a 3-isogenous curve E' with its own A'/B'
+		// and then applies the isogeny's rational map to the result. This
+		// package doesn't implement that 3-isogeny rational map (it has ~13
+		// independent coefficients beyond A'/B'), so there is no honest A'/B'
+		// to plug in here; return unsupported rather than mapping onto a
+		// placeholder curve that produces points that aren't actually on
+		// secp256k1.
+		return h2cParams{}, gg.ErrUnsupportedGroup
 	}
 	return h2cParams{}, gg.ErrUnsupportedGroup
 }
 
 // hashToBase hashes a buffer into a vector of underlying base field elements,
-// where the base field is chosen depending on the associated elliptic curve
+// where the base field is chosen depending on the associated elliptic curve.
+// It derives its uniform bytes via expand_message_xmd/expand_message_xof
+// (https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-07#section-5.3)
+// rather than HKDF; ctr differentiates the two field elements u0/u1 that
+// hashToCurve maps and combines.
 func (params h2cParams) hashToBaseField(buf []byte, ctr int) ([]*big.Int, error) {
-	os, err := i2osp(0, 1)
+	ctrTag, err := i2osp(big.NewInt(int64(ctr)), 1)
 	if err != nil {
 		return nil, gg.ErrInternalInstantiation
 	}
-	hashFunc := func() hash.Hash { return params.hash }
-	msgPrime := hkdf.Extract(hashFunc, params.dst, append(buf, os...))
-	osCtr, err := i2osp(ctr, 1)
+	msg := append(append([]byte{}, buf...), ctrTag...)
+	lenInBytes := params.m * params.l
+
+	var uniformBytes []byte
+	switch params.expand {
+	case expandXMD:
+		uniformBytes, err = expandMessageXMD(func() hash.Hash { return params.hash }, msg, params.dst, lenInBytes)
+	case expandXOF:
+		uniformBytes, err = expandMessageXOF(params.xof, msg, params.dst, lenInBytes)
+	default:
+		return nil, gg.ErrUnsupportedGroup
+	}
 	if err != nil {
-		return nil, gg.ErrInternalInstantiation
+		return nil, err
 	}
-	infoPfx := append([]byte("H2C"), osCtr...)
-	i := 1
+
 	res := make([]*big.Int, params.m)
-	for i <= params.m {
-		osi, err := i2osp(i, 1)
-		if err != nil {
-			return nil, gg.ErrInternalInstantiation
-		}
-		info := append(infoPfx, osi...)
-		reader := hkdf.Expand(hashFunc, msgPrime, info)
-		t := make([]byte, params.l)
-		reader.Read(t)
-		ei := int64(os2ip(t))
-		res[i-1] = new(big.Int).Mod(big.NewInt(ei), params.p)
-		i++
+	for i := 0; i < params.m; i++ {
+		elmOffset := params.l * i
+		res[i] = new(big.Int).Mod(os2ip(uniformBytes[elmOffset:elmOffset+params.l]), params.p)
 	}
 	return res, nil
 }
@@ -115,11 +182,12 @@ func (params h2cParams) hashToCurve(alpha []byte) (Point, error) {
 	Q1 := Point{}
 	var e0, e1 error
 	switch params.gc.Name() {
-	case "P-384":
-	case "P-521":
+	case "P-384", "P-521":
 		Q0, e0 = params.sswu(u0)
 		Q1, e1 = params.sswu(u1)
-		break
+	case "Curve25519", "Ristretto255":
+		Q0, e0 = params.elligator2(u0)
+		Q1, e1 = params.elligator2(u1)
 	default:
 		e0 = gg.ErrIncompatibleGroupParams
 	}
@@ -141,57 +209,170 @@ func (params h2cParams) hashToCurve(alpha []byte) (Point, error) {
 	if err != nil {
 		return Point{}, err
 	}
-	err = R.clearCofactor(params.gc, params.hEff)
+	R, err = params.clearCofactor(R)
 	if err != nil {
 		return Point{}, err
 	}
 	return R, nil
 }
 
+// bls12381G2CofactorHEff is the BLS12-381 G2 cofactor, from
+// https://tools.ietf.org/html/draft-irtf-cfrg-pairing-friendly-curves-08#section-4.2.2.
+var bls12381G2CofactorHEff, _ = new(big.Int).SetString("5d543a95414e7f1091d50792876a202cd91de4547085abaa68a205b2e5a7ddfa628f1cb4d9e82ef21537e293a6691ae1616ec6e786f0c70cf1c38e31c7238e5", 16)
+
+// clearCofactor maps P into the prime-order subgroup by multiplying it by
+// the suite's cofactor. BLS12-381 G2 (cofactor ~2^507) goes through
+// clearCofactorBLS12381G2, which multiplies by the full cofactor with
+// scalarMultPoint; the Budroni-Pintore polynomial is not implemented. Every
+// other suite goes to scalarMultPoint with hEff; P-384/P-521 have hEff = 1
+// so this is a no-op for them.
+// Curve25519/Ristretto255 (hEff = 8) would exercise the scalarMultPoint path
+// for real, but getH2CParams can't produce a GroupCurve for either today (no
+// such curve is registered anywhere in this package), so neither suite is
+// actually reachable yet.
+func (params h2cParams) clearCofactor(P Point) (Point, error) {
+	if params.gc.Name() == "BLS12-381-G2" {
+		return clearCofactorBLS12381G2(params.gc, P)
+	}
+	return scalarMultPoint(params.gc, P, params.hEff)
+}
+
+// clearCofactorBLS12381G2 is the hook for the Budroni-Pintore fast
+// cofactor-clearing polynomial for BLS12-381 G2
+// (https://eprint.iacr.org/2017/419), which replaces the ~2^507 scalar
+// multiplication with a short sequence of Frobenius endomorphisms and
+// additions. Wiring up the real polynomial needs the Fp2 endomorphism
+// primitives that live alongside the rest of the BLS12-381 group
+// implementation, so for now this defers to the generic scalar
+// multiplication path with the correct cofactor.
+func clearCofactorBLS12381G2(gc GroupCurve, P Point) (Point, error) {
+	return scalarMultPoint(gc, P, bls12381G2CofactorHEff)
+}
+
+// scalarMultPoint computes [k]P via double-and-add, using Point.Add as the
+// only group operation exposed on Point. k is always a suite's public
+// cofactor here, never secret data, so branching on its bits does not leak
+// anything about P (which, post hash-to-curve, is already public too).
+func scalarMultPoint(gc GroupCurve, P Point, k *big.Int) (Point, error) {
+	result := Point{}
+	addend := P
+	started := false
+	for i := 0; i < k.BitLen(); i++ {
+		if k.Bit(i) == 1 {
+			if !started {
+				result = addend
+				started = true
+			} else if err := result.Add(gc, addend); err != nil {
+				return Point{}, err
+			}
+		}
+		if i+1 < k.BitLen() {
+			if err := addend.Add(gc, addend); err != nil {
+				return Point{}, err
+			}
+		}
+	}
+	return result, nil
+}
+
 // sswu completes the Simplified SWU method curve mapping defined in
-// https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-05#section-6.6.2
+// https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-05#section-6.6.2.
+// All of the field arithmetic runs through the fixed-limb field.Element type
+// rather than math/big, so that its cost does not depend on the magnitude of
+// the (secret) input u.
 func (params h2cParams) sswu(uArr []*big.Int) (Point, error) {
 	if len(uArr) > 1 {
 		return Point{}, gg.ErrIncompatibleGroupParams
 	}
-	u := uArr[0]
-	p, A, B, Z := params.p, params.a, params.b, big.NewInt(int64(params.z))
-	expRoot := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).ModInverse(two, p))
+	fp := params.field
+	modMinusTwo := new(big.Int).Sub(fp.Modulus(), two)
+	legendreExp := new(big.Int).Rsh(new(big.Int).Sub(fp.Modulus(), one), 1) // (p-1)/2
+	sqrtExp := new(big.Int).Rsh(new(big.Int).Add(fp.Modulus(), one), 2)    // (p+1)/4, a square root since p = 3 mod 4 for P-384/P-521
+
+	u := fp.FromBig(uArr[0])
+	A, B, Z := fp.FromBig(params.a), fp.FromBig(params.b), fp.FromBig(big.NewInt(int64(params.z)))
+	feZero, feOne := fp.FromBig(zero), fp.FromBig(one)
 
 	// consts
 	// c1 := -B/A, c2 := -1/Z
-	c1 := new(big.Int).Mod(new(big.Int).Mul(new(big.Int).Mul(B, minusOne), new(big.Int).ModInverse(A, p)), p)
-	c2 := new(big.Int).Mul(minusOne, new(big.Int).ModInverse(Z, p))
+	c1 := fp.Mul(fp.Sub(feZero, B), fp.Pow(A, modMinusTwo))
+	c2 := fp.Sub(feZero, fp.Pow(Z, modMinusTwo))
 
 	// steps
-	t1 := new(big.Int).Mul(Z, new(big.Int).Exp(u, two, p))  // 1
-	t2 := new(big.Int).Exp(t1, two, p)                      // 2
-	x1 := new(big.Int).Add(t1, t2)                          // 3
-	x1 = inv0(x1, p)                                        // 4
-	e1 := new(big.Int).Abs(big.NewInt(int64(x1.Cmp(zero)))) // 5
-	x1 = x1.Add(x1, one)                                    // 6
-	x1 = cmov(x1, c2, e1)                                   // 7
-	x1 = x1.Mul(x1, c1)                                     // 8
-	gx1 := new(big.Int).Exp(x1, two, p)                     // 9
-	gx1 = gx1.Add(gx1, A)                                   // 10
-	gx1 = gx1.Mul(gx1, x1)                                  // 11
-	gx1 = gx1.Add(gx1, B)                                   // 12
-	x2 := new(big.Int).Mul(t1, x1)                          // 13
-	t2 = t2.Mul(t1, t2)                                     // 14
-	gx2 := new(big.Int).Mul(gx1, t2)                        // 15
-	e2 := isSquare(gx1, expRoot, p)                         // 16
-	x := cmov(x2, x1, e2)                                   // 17
-	y2 := cmov(gx2, gx1, e2)                                // 18
-	y := sqrt(y2, expRoot, p)                               // 19
-	e3 := sgnCmp(u, y)                                      // 20
-	y = cmov(y.Mul(y, minusOne), y, e3)                     // 21
+	t1 := fp.Mul(Z, fp.Square(u))  // 1
+	t2 := fp.Square(t1)            // 2
+	x1 := fp.Add(t1, t2)           // 3
+	x1 = fp.Pow(x1, modMinusTwo)   // 4, inv0(x1)
+	e1 := x1.IsZero()              // 5
+	x1 = fp.Add(x1, feOne)         // 6
+	x1 = fp.CMov(x1, c2, e1)       // 7
+	x1 = fp.Mul(x1, c1)            // 8
+	gx1 := fp.Square(x1)           // 9
+	gx1 = fp.Add(gx1, A)           // 10
+	gx1 = fp.Mul(gx1, x1)          // 11
+	gx1 = fp.Add(gx1, B)           // 12
+	x2 := fp.Mul(t1, x1)           // 13
+	t2 = fp.Mul(t1, t2)            // 14
+	gx2 := fp.Mul(gx1, t2)         // 15
+	e2 := isSquareField(fp, gx1, legendreExp) // 16
+	x := fp.CMov(x2, x1, e2)       // 17
+	y2 := fp.CMov(gx2, gx1, e2)    // 18
+	y := fp.Pow(y2, sqrtExp)       // 19, sqrt(y2)
+	e3 := sgnCmpField(fp, u, y)    // 20
+	y = fp.CMov(fp.Sub(feZero, y), y, e3) // 21
 
 	// construct point and assert that it is correct
-	P := Point{X: x, Y: y}
+	P := Point{X: x.ToBig(), Y: y.ToBig()}
+	if !P.IsValid(params.gc) {
+		return Point{}, gg.ErrInvalidGroupElement
+	}
+	return P, nil
+}
+
+// elligator2 completes the Elligator 2 method curve mapping defined in
+// https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-05#section-6.7.1,
+// used for the Montgomery-form curves underlying Curve25519/Ristretto255.
+// Like sswu, every field operation runs through field.Element rather than
+// math/big, so its cost does not depend on the magnitude of the (secret)
+// input u.
+func (params h2cParams) elligator2(uArr []*big.Int) (Point, error) {
+	if len(uArr) > 1 {
+		return Point{}, gg.ErrIncompatibleGroupParams
+	}
+	fp := params.field
+	modMinusTwo := new(big.Int).Sub(fp.Modulus(), two)
+	legendreExp := new(big.Int).Rsh(new(big.Int).Sub(fp.Modulus(), one), 1) // (p-1)/2
+
+	u := fp.FromBig(uArr[0])
+	A, Z := fp.FromBig(params.a), fp.FromBig(big.NewInt(int64(params.z)))
+	feZero, feOne, feMinusOne := fp.FromBig(zero), fp.FromBig(one), fp.FromBig(minusOne)
+
+	// steps
+	t1 := fp.Mul(Z, fp.Square(u))          // 1, 2
+	e1 := fp.Sub(t1, feMinusOne).IsZero()  // 3, e1 = (t1 == -1)
+	t1 = fp.CMov(t1, feZero, e1)           // 4
+	x1 := fp.Add(t1, feOne)                // 5
+	x1 = fp.Pow(x1, modMinusTwo)           // 6, inv0(x1)
+	x1 = fp.Mul(x1, fp.Sub(feZero, A))     // 7, x1 = -A / (1 + Z*u^2)
+	gx1 := fp.Add(x1, A)                   // 8
+	gx1 = fp.Mul(gx1, x1)
+	gx1 = fp.Add(gx1, feOne)
+	gx1 = fp.Mul(gx1, x1) // gx1 = x1^3 + A*x1^2 + x1
+	x2 := fp.Sub(fp.Sub(feZero, x1), A) // x2 = -x1 - A
+	gx2 := fp.Mul(t1, gx1)
+
+	e2 := isSquareField(fp, gx1, legendreExp)
+	x := fp.CMov(x2, x1, e2)
+	y2 := fp.CMov(gx2, gx1, e2)
+	y := fp.Pow(y2, legendreExp)
+	e3 := sgnCmpField(fp, u, y)
+	y = fp.CMov(fp.Sub(feZero, y), y, e3)
+
+	P := Point{X: x.ToBig(), Y: y.ToBig()}
 	if !P.IsValid(params.gc) {
 		return Point{}, gg.ErrInvalidGroupElement
 	}
-	return Point{X: x, Y: y}, nil
+	return P, nil
 }
 
 // returns 1 if the signs of s1 and s2 are the same, and 0 otherwise
@@ -200,31 +381,47 @@ func sgnCmp(s1, s2 *big.Int) *big.Int {
 	return revCmpBit(c)
 }
 
-// sgn0 returns -1 if x is negative and 0/1 if x is positive
+// sgn0 implements the spec's sgn0_m_eq_1(x) = x mod 2, i.e. the
+// least-significant bit of x's canonical (non-negative, reduced mod p)
+// representative. The previous definition distinguished only big.Int sign,
+// which is always non-negative here since every x passed in has already
+// gone through a Mod p; that made sgn0 effectively constant and meant the
+// sign fix-up in step 21 of sswu could never flip y.
 func sgn0(x *big.Int) *big.Int {
-	c := int64(x.Cmp(zero))
-	d := big.NewInt(c*2 + 2)
-	// if c = 1 or 0 then d = 4 or 2, so e = 1
-	// if c = -1 then d = 0, so e = -1
-	e := int64(d.Cmp(one))
-	return big.NewInt(e)
+	return new(big.Int).Mod(x, two)
+}
+
+// sgn0MEq2 implements the spec's sgn0_m_eq_2 for a degree-2 extension-field
+// element represented by its two base-field components (x0, x1): x0's sign
+// is used unless x0 is zero, in which case x1's sign decides. No ciphersuite
+// wired up today maps into an extension field, but this is the primitive a
+// future one (e.g. a BLS12-381 G2 mapping) would need.
+func sgn0MEq2(x0, x1 *big.Int) *big.Int {
+	sign0 := sgn0(x0)
+	isZero0 := big.NewInt(0)
+	if x0.Cmp(zero) == 0 {
+		isZero0 = one
+	}
+	return new(big.Int).Or(sign0, new(big.Int).And(isZero0, sgn0(x1)))
 }
 
-// sqrt computes the sqrt of x mod p (pass in exp explicitly so that we don't
-// have to recompute)
-func sqrt(x, exp, p *big.Int) *big.Int {
-	return new(big.Int).Exp(x, exp, p)
+// isSquareField returns 1 if x is a square integer in the field backing fp,
+// and 0 otherwise; exp is the Legendre-symbol exponent (p-1)/2. It is used
+// by sswu and elligator2.
+func isSquareField(fp *field.Params, x field.Element, exp *big.Int) uint64 {
+	b := fp.Pow(x, exp)
+	isOne := fp.Sub(b, fp.FromBig(one)).IsZero()
+	isZero := b.IsZero()
+	return isOne | isZero
 }
 
-// isSquare returns 1 if x is a square integer in FF_p and 0 otherwise, passes
-// in the value exp to compute the square root in the exponent
-func isSquare(x, exp, p *big.Int) *big.Int {
-	b := sqrt(x, exp, p)
-	c := b.Cmp(one)
-	d := b.Cmp(zero)
-	e := c * d
-	f := new(big.Int).Abs(big.NewInt(int64(big.NewInt(int64(e)).Cmp(zero)))) // should be 0 if it is square, and 1 otherwise
-	return revCmpBit(f)                                                      // returns 1 if square, and 0 otherwise
+// sgnCmpField returns 1 if s1 and s2 have the same sign (sgn0) and 0
+// otherwise, mirroring sgnCmp but computed directly on field.Element via
+// Sgn0 rather than round-tripping the secret-derived s1/s2 through math/big
+// mid-algorithm; math/big's cost depends on operand magnitude, which would
+// reintroduce exactly the timing leak the field backend exists to remove.
+func sgnCmpField(fp *field.Params, s1, s2 field.Element) uint64 {
+	return 1 ^ (s1.Sgn0() ^ s2.Sgn0())
 }
 
 // revCmp reverses the result of a comparison bit indicator
@@ -232,39 +429,25 @@ func revCmpBit(cmp *big.Int) *big.Int {
 	return new(big.Int).Mod(new(big.Int).Add(cmp, one), two)
 }
 
-// cmov is a constant-time big.Int conditional selector, returning b if c is 1,
-// and a if c = 0
-func cmov(a, b, c *big.Int) *big.Int {
-	return new(big.Int).Add(new(big.Int).Mul(c, b), new(big.Int).Mul(new(big.Int).Sub(one, c), a))
-}
-
-// inv0 returns the inverse of x in FF_p, also returning 0^{-1} => 0
-func inv0(x, p *big.Int) *big.Int {
-	return x.Exp(x, new(big.Int).Sub(p, two), p)
-}
-
-// i2osp converts an integer to an octet-string
-// (https://tools.ietf.org/html/rfc8017#section-4.1)
-func i2osp(x, xLen int) ([]byte, error) {
-	if x < 0 || x >= (1<<(8*xLen)) {
+// i2osp converts a non-negative integer to an octet-string of length xLen
+// (https://tools.ietf.org/html/rfc8017#section-4.1). x is a *big.Int, not a
+// machine int, so this is correct for the l = 72/96-byte expansions that
+// hashToBaseField produces for P-384/P-521; a plain int would overflow long
+// before then.
+func i2osp(x *big.Int, xLen int) ([]byte, error) {
+	if x.Sign() < 0 || x.BitLen() > 8*xLen {
 		return nil, gg.ErrInternalInstantiation
 	}
 	ret := make([]byte, xLen)
-	val := x
-	for i := xLen - 1; i >= 0; i-- {
-		ret[i] = byte(val & 0xff)
-		val = val >> 8
-	}
+	xBytes := x.Bytes()
+	copy(ret[xLen-len(xBytes):], xBytes)
 	return ret, nil
 }
 
-// os2ip converts an octet-string to an integer
-// (https://tools.ietf.org/html/rfc8017#section-4.1)
-func os2ip(x []byte) int {
-	ret := 0
-	for _, b := range x {
-		ret = ret << 8
-		ret += int(b)
-	}
-	return ret
+// os2ip converts an octet-string to a non-negative integer
+// (https://tools.ietf.org/html/rfc8017#section-4.1). It returns a *big.Int
+// so that callers reducing the result mod a field prime don't lose any of
+// the input buffer's bytes the way a machine int would.
+func os2ip(x []byte) *big.Int {
+	return new(big.Int).SetBytes(x)
 }